@@ -37,6 +37,7 @@ func main() {
 			if err := command.Validate(flags.Args, &cfg); err != nil {
 				return errors.Wrap(err, "invalid argument")
 			}
+			cfg.LogLevelSet = c.IsSet("log-level")
 			return snapshotter.Start(ctx, cfg)
 		},
 	}
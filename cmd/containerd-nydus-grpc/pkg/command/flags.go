@@ -48,6 +48,7 @@ type Args struct {
 	LogToStdout          bool
 	EnableNydusOverlayFS bool
 	NydusdThreadNum      int
+	DisableLogRedaction  bool
 }
 
 type Flags struct {
@@ -186,6 +187,12 @@ func buildFlags(args *Args) []cli.Flag {
 			Usage:       "Nydusd daemon thread-num, default will be set to the number of CPUs",
 			Destination: &args.NydusdThreadNum,
 		},
+		&cli.BoolFlag{
+			Name:        "disable-log-redaction",
+			Value:       false,
+			Usage:       "whether to print credentials in logs and errors, only for debugging",
+			Destination: &args.DisableLogRedaction,
+		},
 	}
 }
 
@@ -240,6 +247,7 @@ func Validate(args *Args, cfg *config.Config) error {
 	cfg.DisableCacheManager = args.DisableCacheManager
 	cfg.EnableNydusOverlayFS = args.EnableNydusOverlayFS
 	cfg.NydusdThreadNum = args.NydusdThreadNum
+	cfg.DisableLogRedaction = args.DisableLogRedaction
 
 	d, err := time.ParseDuration(args.GCPeriod)
 	if err != nil {
@@ -7,11 +7,14 @@
 package config
 
 import (
+	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/pkg/errors"
 	exec "golang.org/x/sys/execabs"
+
+	"github.com/containerd/nydus-snapshotter/pkg/errdefs"
 )
 
 const (
@@ -26,6 +29,7 @@ const (
 	defaultNydusDaemonConfigPath string = "/etc/nydus/config.json"
 	nydusdBinaryName             string = "nydusd"
 	nydusImageBinaryName         string = "nydus-image"
+	nydusReleaseURL              string = "https://github.com/dragonflyoss/image-service/releases"
 )
 
 type Config struct {
@@ -46,11 +50,13 @@ type Config struct {
 	MetricsFile          string        `toml:"metrics_file"`
 	EnableStargz         bool          `toml:"enable_stargz"`
 	LogLevel             string        `toml:"-"`
+	LogLevelSet          bool          `toml:"-"`
 	LogDir               string        `toml:"log_dir"`
 	LogToStdout          bool          `toml:"log_to_stdout"`
 	DisableCacheManager  bool          `toml:"disable_cache_manager"`
 	EnableNydusOverlayFS bool          `toml:"enable_nydus_overlayfs"`
 	NydusdThreadNum      int           `toml:"nydusd_thread_num"`
+	DisableLogRedaction  bool          `toml:"disable_log_redaction"`
 }
 
 func (c *Config) FillupWithDefaults() error {
@@ -86,22 +92,45 @@ func (c *Config) FillupWithDefaults() error {
 
 func (c *Config) SetupNydusBinaryPaths() error {
 	// resolve nydusd path
-	if c.NydusdBinaryPath == "" {
-		path, err := exec.LookPath(nydusdBinaryName)
-		if err != nil {
+	path, err := lookupBinary(nydusdBinaryName, c.NydusdBinaryPath, "nydusd-path", "nydusd_binary_path")
+	if err != nil {
+		return err
+	}
+	c.NydusdBinaryPath = path
+
+	// resolve nydus-image path, it's only needed to build bootstraps for
+	// stargz images, so don't fail if it's missing and stargz is disabled.
+	path, err = lookupBinary(nydusImageBinaryName, c.NydusImageBinaryPath, "nydusimg-path", "nydus_image_binary")
+	if err != nil {
+		if c.EnableStargz {
 			return err
 		}
-		c.NydusdBinaryPath = path
+		path = ""
 	}
+	c.NydusImageBinaryPath = path
+
+	return nil
+}
 
-	// resolve nydus-image path
-	if c.NydusImageBinaryPath == "" {
-		path, err := exec.LookPath(nydusImageBinaryName)
+// lookupBinary resolves the binary name from $PATH, or checks the configured
+// path is executable, and returns an error wrapping errdefs.ErrBinaryNotFound
+// with the searched paths and an install hint otherwise. flag and key are the
+// CLI flag and the TOML configuration key setting the binary path.
+func lookupBinary(name, configured, flag, key string) (string, error) {
+	if configured != "" {
+		path, err := exec.LookPath(configured)
 		if err != nil {
-			return err
+			return "", errors.Wrapf(errdefs.ErrBinaryNotFound,
+				"%s is not an executable %s binary: %v", configured, name, err)
 		}
-		c.NydusImageBinaryPath = path
+		return path, nil
 	}
 
-	return nil
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", errors.Wrapf(errdefs.ErrBinaryNotFound,
+			"%s not found in $PATH (%s), install it from %s or specify its location with --%s or %s",
+			name, os.Getenv("PATH"), nydusReleaseURL, flag, key)
+	}
+	return path, nil
 }
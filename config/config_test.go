@@ -0,0 +1,56 @@
+/*
+ * Copyright (c) 2022. Ant Group. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/containerd/nydus-snapshotter/pkg/errdefs"
+)
+
+func TestSetupNydusBinaryPaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nydus-bin-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	oldPath := os.Getenv("PATH")
+	defer os.Setenv("PATH", oldPath)
+	require.Nil(t, os.Setenv("PATH", dir))
+
+	// nydusd is always required.
+	var cfg Config
+	err = cfg.SetupNydusBinaryPaths()
+	require.True(t, errdefs.IsBinaryNotFound(err))
+	require.Contains(t, err.Error(), dir)
+	require.Contains(t, err.Error(), "--nydusd-path")
+	require.Contains(t, err.Error(), "nydusd_binary_path")
+
+	nydusd := filepath.Join(dir, nydusdBinaryName)
+	require.Nil(t, ioutil.WriteFile(nydusd, []byte("#!/bin/sh\n"), 0755))
+
+	// nydus-image is only required when stargz is enabled.
+	cfg = Config{}
+	require.Nil(t, cfg.SetupNydusBinaryPaths())
+	require.Equal(t, nydusd, cfg.NydusdBinaryPath)
+	require.Equal(t, "", cfg.NydusImageBinaryPath)
+
+	cfg = Config{EnableStargz: true}
+	err = cfg.SetupNydusBinaryPaths()
+	require.True(t, errdefs.IsBinaryNotFound(err))
+	require.Contains(t, err.Error(), "--nydusimg-path")
+	require.Contains(t, err.Error(), "nydus_image_binary")
+
+	// Configured paths must point to executables.
+	cfg = Config{NydusdBinaryPath: filepath.Join(dir, "missing")}
+	err = cfg.SetupNydusBinaryPaths()
+	require.True(t, errdefs.IsBinaryNotFound(err))
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/containerd/nydus-snapshotter/pkg/auth"
+	"github.com/containerd/nydus-snapshotter/pkg/utils/file"
+	"github.com/containerd/nydus-snapshotter/pkg/utils/redact"
 	"github.com/containerd/nydus-snapshotter/pkg/utils/registry"
 )
 
@@ -100,9 +102,19 @@ func LoadConfig(configFile string, cfg *DaemonConfig) error {
 func SaveConfig(c DaemonConfig, configFile string) error {
 	b, err := json.Marshal(c)
 	if err != nil {
-		return nil
+		return err
 	}
-	return ioutil.WriteFile(configFile, b, 0755)
+	return file.AtomicWriteFile(configFile, b, 0600)
+}
+
+// Redacted returns a copy of the configuration with backend credentials
+// masked, so that it can be logged or formatted into errors.
+func (c DaemonConfig) Redacted() DaemonConfig {
+	backend := &c.Device.Backend.Config
+	backend.Auth = redact.Secret(backend.Auth)
+	backend.RegistryToken = redact.Secret(backend.RegistryToken)
+	backend.AccessKeySecret = redact.Secret(backend.AccessKeySecret)
+	return c
 }
 
 func NewDaemonConfig(cfg DaemonConfig, imageID string, vpcRegistry bool, labels map[string]string) (DaemonConfig, error) {
@@ -133,9 +145,20 @@ func NewDaemonConfig(cfg DaemonConfig, imageID string, vpcRegistry bool, labels
 		}
 		cfg.Device.Backend.Config.Host = registryHost
 		cfg.Device.Backend.Config.Repo = image.Repo
-	// Localfs and OSS backends don't need any update, just use the provided config in template
-	case backendTypeLocalfs:
 	case backendTypeOss:
+		// Fill in access keys from env or the shared credentials file, so
+		// that they don't have to be put in the config template. nydusd
+		// only takes them from its config, so they still end up in the
+		// per-daemon config files and in nydus-overlayfs mount options.
+		backend := &cfg.Device.Backend.Config
+		if backend.AccessKeyID == "" && backend.AccessKeySecret == "" {
+			if cred := auth.GetOSSCredential(); cred != nil {
+				backend.AccessKeyID = cred.AccessKeyID
+				backend.AccessKeySecret = cred.AccessKeySecret
+			}
+		}
+	// Localfs backend doesn't need any update, just use the provided config in template
+	case backendTypeLocalfs:
 	default:
 		return DaemonConfig{}, errors.Errorf("unknown backend type %s", backend)
 	}
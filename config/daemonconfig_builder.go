@@ -0,0 +1,163 @@
+/*
+ * Copyright (c) 2022. Ant Group. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package config
+
+import (
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultRafsMode = "direct"
+	cacheTypeBlob   = "blobcache"
+)
+
+// DaemonConfigOpt describes one piece of intent used to build a nydusd
+// configuration with BuildDaemonConfig.
+type DaemonConfigOpt func(c *DaemonConfig) error
+
+// WithRegistryBackend makes nydusd fetch blobs from an image registry. auth is
+// the base64 encoded "username:password" pair and may be empty for public
+// repositories. host and repo may be left empty in templates, NewDaemonConfig
+// fills them from the image reference of each snapshot.
+func WithRegistryBackend(scheme, host, repo, auth string) DaemonConfigOpt {
+	return func(c *DaemonConfig) error {
+		c.Device.Backend.BackendType = backendTypeRegistry
+		c.Device.Backend.Config.Scheme = scheme
+		c.Device.Backend.Config.Host = host
+		c.Device.Backend.Config.Repo = repo
+		c.Device.Backend.Config.Auth = auth
+		return nil
+	}
+}
+
+// WithOSSBackend makes nydusd fetch blobs from an OSS bucket.
+func WithOSSBackend(scheme, endpoint, bucket, objectPrefix, accessKeyID, accessKeySecret string) DaemonConfigOpt {
+	return func(c *DaemonConfig) error {
+		c.Device.Backend.BackendType = backendTypeOss
+		c.Device.Backend.Config.Scheme = scheme
+		c.Device.Backend.Config.EndPoint = endpoint
+		c.Device.Backend.Config.BucketName = bucket
+		c.Device.Backend.Config.ObjectPrefix = objectPrefix
+		c.Device.Backend.Config.AccessKeyID = accessKeyID
+		c.Device.Backend.Config.AccessKeySecret = accessKeySecret
+		return nil
+	}
+}
+
+// WithLocalfsBackend makes nydusd read blobs from a local directory.
+func WithLocalfsBackend(dir string) DaemonConfigOpt {
+	return func(c *DaemonConfig) error {
+		c.Device.Backend.BackendType = backendTypeLocalfs
+		c.Device.Backend.Config.Dir = dir
+		return nil
+	}
+}
+
+// WithMirror sends backend requests through a mirror or P2P proxy, falling
+// back to the backend itself when the proxy is unhealthy if fallback is set.
+func WithMirror(url, pingURL string, fallback bool, checkInterval int) DaemonConfigOpt {
+	return func(c *DaemonConfig) error {
+		if url == "" {
+			return errors.New("mirror url is empty")
+		}
+		c.Device.Backend.Config.Proxy.URL = url
+		c.Device.Backend.Config.Proxy.PingURL = pingURL
+		c.Device.Backend.Config.Proxy.Fallback = fallback
+		c.Device.Backend.Config.Proxy.CheckInterval = checkInterval
+		return nil
+	}
+}
+
+// WithBlobCache caches fetched blob data in workDir.
+func WithBlobCache(workDir string, compressed bool) DaemonConfigOpt {
+	return func(c *DaemonConfig) error {
+		c.Device.Cache.CacheType = cacheTypeBlob
+		c.Device.Cache.Compressed = compressed
+		c.Device.Cache.Config.WorkDir = workDir
+		return nil
+	}
+}
+
+// WithPrefetch enables filesystem level prefetch. prefetchAll prefetches the
+// whole image rather than only the files hinted in the bootstrap.
+func WithPrefetch(prefetchAll bool, threadsCount, mergingSize int) DaemonConfigOpt {
+	return func(c *DaemonConfig) error {
+		if threadsCount < 0 || mergingSize < 0 {
+			return errors.New("prefetch threads count and merging size must not be negative")
+		}
+		c.FSPrefetch.Enable = true
+		c.FSPrefetch.PrefetchAll = prefetchAll
+		c.FSPrefetch.ThreadsCount = threadsCount
+		c.FSPrefetch.MergingSize = mergingSize
+		return nil
+	}
+}
+
+// WithDigestValidate makes nydusd validate chunk digests on read.
+func WithDigestValidate(validate bool) DaemonConfigOpt {
+	return func(c *DaemonConfig) error {
+		c.DigestValidate = validate
+		return nil
+	}
+}
+
+// BuildDaemonConfig builds a validated fusedev nydusd configuration from
+// high-level intent, which can be written with SaveConfig and used as the
+// snapshotter's daemon config template.
+func BuildDaemonConfig(opts ...DaemonConfigOpt) (DaemonConfig, error) {
+	var cfg DaemonConfig
+	cfg.Mode = defaultRafsMode
+	for _, o := range opts {
+		if err := o(&cfg); err != nil {
+			return DaemonConfig{}, err
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return DaemonConfig{}, err
+	}
+	return cfg, nil
+}
+
+// Validate checks the configuration is a usable daemon config template.
+// Registry host and repo are not required, they are filled per image by
+// NewDaemonConfig.
+func (c *DaemonConfig) Validate() error {
+	backend := c.Device.Backend.Config
+	switch c.Device.Backend.BackendType {
+	case backendTypeRegistry:
+	case backendTypeOss:
+		if backend.EndPoint == "" || backend.BucketName == "" {
+			return errors.New("oss backend requires endpoint and bucket name")
+		}
+	case backendTypeLocalfs:
+		if backend.Dir == "" && backend.BlobFile == "" {
+			return errors.New("localfs backend requires blob dir or blob file")
+		}
+	case "":
+		return errors.New("backend type is not set")
+	default:
+		return errors.Errorf("unknown backend type %s", c.Device.Backend.BackendType)
+	}
+
+	switch c.Device.Cache.CacheType {
+	case "":
+	case cacheTypeBlob:
+		if c.Device.Cache.Config.WorkDir == "" {
+			return errors.New("blob cache requires work dir")
+		}
+	default:
+		return errors.Errorf("unknown cache type %s", c.Device.Cache.CacheType)
+	}
+
+	switch c.Mode {
+	case "direct", "cached":
+	default:
+		return errors.Errorf("unknown rafs mode %s", c.Mode)
+	}
+
+	return nil
+}
@@ -8,6 +8,9 @@ package config
 
 import (
 	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -61,3 +64,74 @@ func TestLoadConfig(t *testing.T) {
 	require.Equal(t, cfg.Device.Backend.Config.BlobURLScheme, "http")
 	require.Equal(t, cfg.Device.Backend.Config.Proxy.CheckInterval, 5)
 }
+
+func TestSaveConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nydus-config-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var cfg DaemonConfig
+	cfg.Device.Backend.BackendType = backendTypeRegistry
+	cfg.Device.Backend.Config.Auth = "secret"
+	configFile := filepath.Join(dir, "config.json")
+	require.Nil(t, SaveConfig(cfg, configFile))
+
+	// The config carries backend credentials, only the owner may read it.
+	info, err := os.Stat(configFile)
+	require.Nil(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	var loaded DaemonConfig
+	require.Nil(t, LoadConfig(configFile, &loaded))
+	require.Equal(t, cfg, loaded)
+}
+
+func TestBuildDaemonConfig(t *testing.T) {
+	cfg, err := BuildDaemonConfig(
+		WithRegistryBackend("https", "registry.example.com", "library/nginx", ""),
+		WithMirror("http://p2p-proxy:65001", "http://p2p-proxy:40901/server/ping", true, 5),
+		WithBlobCache("/cache", false),
+		WithPrefetch(false, 10, 131072),
+	)
+	require.Nil(t, err)
+	require.Equal(t, "direct", cfg.Mode)
+	require.Equal(t, "registry", cfg.Device.Backend.BackendType)
+	require.Equal(t, "library/nginx", cfg.Device.Backend.Config.Repo)
+	require.Equal(t, "http://p2p-proxy:65001", cfg.Device.Backend.Config.Proxy.URL)
+	require.Equal(t, "blobcache", cfg.Device.Cache.CacheType)
+	require.Equal(t, "/cache", cfg.Device.Cache.Config.WorkDir)
+	require.True(t, cfg.FSPrefetch.Enable)
+
+	_, err = BuildDaemonConfig(WithBlobCache("/cache", false))
+	require.NotNil(t, err)
+
+	_, err = BuildDaemonConfig(WithOSSBackend("https", "oss-cn-hangzhou.aliyuncs.com", "", "", "", ""))
+	require.NotNil(t, err)
+
+	_, err = BuildDaemonConfig(WithLocalfsBackend("/blobs"))
+	require.Nil(t, err)
+
+	// Registry templates leave host and repo to be filled per image.
+	_, err = BuildDaemonConfig(WithRegistryBackend("https", "", "", ""))
+	require.Nil(t, err)
+
+	var template DaemonConfig
+	require.Nil(t, LoadConfig("../misc/snapshotter/nydusd-config.json", &template))
+	require.Nil(t, template.Validate())
+}
+
+func TestDaemonConfigRedacted(t *testing.T) {
+	const secret = "s3cr3t-value"
+	var cfg DaemonConfig
+	cfg.Device.Backend.BackendType = backendTypeOss
+	cfg.Device.Backend.Config.Auth = secret
+	cfg.Device.Backend.Config.RegistryToken = secret
+	cfg.Device.Backend.Config.AccessKeySecret = secret
+	cfg.Device.Backend.Config.BucketName = "bucket"
+
+	b, err := json.Marshal(cfg.Redacted())
+	require.Nil(t, err)
+	require.NotContains(t, string(b), secret)
+	require.Contains(t, string(b), "bucket")
+	require.Equal(t, secret, cfg.Device.Backend.Config.AccessKeySecret)
+}
@@ -26,7 +26,7 @@ func init() {
 				cfg.RootDir = ic.Root
 			}
 			if err := cfg.FillupWithDefaults(); err != nil {
-				return nil, errors.New("failed to fillup nydus configuration with defaults")
+				return nil, errors.Wrap(err, "failed to fillup nydus configuration with defaults")
 			}
 
 			rs, err := snapshot.NewSnapshotter(ic.Context, cfg)
@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2022. Ant Group. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package auth
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ossAccessKeyIDEnv     = "ALIBABA_CLOUD_ACCESS_KEY_ID"
+	ossAccessKeySecretEnv = "ALIBABA_CLOUD_ACCESS_KEY_SECRET"
+	ossCredentialsFileEnv = "ALIBABA_CLOUD_CREDENTIALS_FILE"
+
+	ossCredentialsProfile = "default"
+	ossAccessKeyType      = "access_key"
+)
+
+// OSSCredential is an access key pair of an OSS bucket.
+type OSSCredential struct {
+	AccessKeyID     string
+	AccessKeySecret string
+}
+
+// GetOSSCredential gets OSS access keys from (ordered):
+// 1. ALIBABA_CLOUD_ACCESS_KEY_ID and ALIBABA_CLOUD_ACCESS_KEY_SECRET env
+// 2. the default profile of the shared credentials file, which is
+// $ALIBABA_CLOUD_CREDENTIALS_FILE or ~/.alibabacloud/credentials
+// Returned `nil` means no access keys are found. The keys are still written
+// into the daemon config by callers, this only keeps them out of templates.
+func GetOSSCredential() *OSSCredential {
+	if cred := ossCredentialFromEnv(); cred != nil {
+		return cred
+	}
+	return ossCredentialFromFile(ossCredentialsFile())
+}
+
+func ossCredentialFromEnv() *OSSCredential {
+	id, secret := os.Getenv(ossAccessKeyIDEnv), os.Getenv(ossAccessKeySecretEnv)
+	if id == "" || secret == "" {
+		return nil
+	}
+	return &OSSCredential{
+		AccessKeyID:     id,
+		AccessKeySecret: secret,
+	}
+}
+
+func ossCredentialsFile() string {
+	if path := os.Getenv(ossCredentialsFileEnv); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".alibabacloud", "credentials")
+}
+
+// ossCredentialFromFile reads the access keys of the default profile in an
+// ini formatted credentials file, like:
+//
+//	[default]
+//	type = access_key
+//	access_key_id = <id>
+//	access_key_secret = <secret>
+func ossCredentialFromFile(path string) *OSSCredential {
+	if path == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logrus.WithError(err).Warnf("failed to open oss credentials file %s", path)
+		}
+		return nil
+	}
+	defer f.Close()
+
+	var (
+		profile string
+		values  = make(map[string]string)
+	)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			profile = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if profile != ossCredentialsProfile {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		values[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	if err := scanner.Err(); err != nil {
+		logrus.WithError(err).Warnf("failed to read oss credentials file %s", path)
+		return nil
+	}
+
+	if t, ok := values["type"]; ok && t != ossAccessKeyType {
+		logrus.Infof("unsupported credential type %s in %s", t, path)
+		return nil
+	}
+	if values["access_key_id"] == "" || values["access_key_secret"] == "" {
+		return nil
+	}
+	return &OSSCredential{
+		AccessKeyID:     values["access_key_id"],
+		AccessKeySecret: values["access_key_secret"],
+	}
+}
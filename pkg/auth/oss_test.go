@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) 2022. Ant Group. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package auth
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testOSSCredentials = `
+# shared credentials
+[other]
+access_key_id = otherid
+access_key_secret = othersecret
+
+[default]
+type = access_key
+access_key_id = fileid
+access_key_secret = filesecret
+`
+
+func TestOSSCredential(t *testing.T) {
+	assert := assert.New(t)
+
+	envs := []string{ossAccessKeyIDEnv, ossAccessKeySecretEnv, ossCredentialsFileEnv}
+	oldEnvs := make(map[string]string)
+	for _, env := range envs {
+		oldEnvs[env] = os.Getenv(env)
+		os.Unsetenv(env)
+	}
+	defer func() {
+		for env, value := range oldEnvs {
+			os.Setenv(env, value)
+		}
+	}()
+
+	dir, err := ioutil.TempDir("", "testoss-")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	// Missing credentials file should get no credential
+	os.Setenv(ossCredentialsFileEnv, filepath.Join(dir, "credentials"))
+	assert.Nil(GetOSSCredential())
+
+	err = ioutil.WriteFile(filepath.Join(dir, "credentials"), []byte(testOSSCredentials), 0600)
+	assert.Nil(err)
+	cred := GetOSSCredential()
+	assert.NotNil(cred)
+	assert.Equal("fileid", cred.AccessKeyID)
+	assert.Equal("filesecret", cred.AccessKeySecret)
+
+	// Env is preferred to credentials file
+	os.Setenv(ossAccessKeyIDEnv, "envid")
+	os.Setenv(ossAccessKeySecretEnv, "envsecret")
+	cred = GetOSSCredential()
+	assert.NotNil(cred)
+	assert.Equal("envid", cred.AccessKeyID)
+	assert.Equal("envsecret", cred.AccessKeySecret)
+
+	// Only access key credentials are supported
+	os.Unsetenv(ossAccessKeyIDEnv)
+	err = ioutil.WriteFile(filepath.Join(dir, "credentials"), []byte("[default]\ntype = ecs_ram_role\n"), 0600)
+	assert.Nil(err)
+	assert.Nil(GetOSSCredential())
+}
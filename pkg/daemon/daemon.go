@@ -24,6 +24,15 @@ const (
 	SharedNydusDaemonID = "shared_daemon"
 )
 
+// Log levels accepted by nydusd.
+var validLogLevels = map[string]bool{
+	"trace": true,
+	"debug": true,
+	"info":  true,
+	"warn":  true,
+	"error": true,
+}
+
 type NewDaemonOpt func(d *Daemon) error
 
 type Daemon struct {
@@ -128,6 +137,18 @@ func (d *Daemon) GetFsMetric(sharedDaemon bool, sid string) (*model.FsMetric, er
 	return d.Client.GetFsMetric(sharedDaemon, sid)
 }
 
+// SetConfig applies conf to the running nydusd. It doesn't update the daemon
+// struct, callers record the new values once they are persisted.
+func (d *Daemon) SetConfig(conf model.DaemonConfig) error {
+	if conf.LogLevel != "" && !validLogLevels[conf.LogLevel] {
+		return errors.Errorf("invalid nydusd log level %q", conf.LogLevel)
+	}
+	if err := d.ensureClient("set config"); err != nil {
+		return err
+	}
+	return d.Client.SetConfig(conf)
+}
+
 func (d *Daemon) IsMultipleDaemon() bool {
 	return d.DaemonMode == config.DaemonModeMultiple
 }
@@ -13,7 +13,8 @@ import (
 )
 
 var (
-	ErrAlreadyExists = errors.New("already exists")
+	ErrAlreadyExists  = errors.New("already exists")
+	ErrBinaryNotFound = errors.New("binary not found")
 )
 
 // IsAlreadyExists returns true if the error is due to already exists
@@ -21,6 +22,11 @@ func IsAlreadyExists(err error) bool {
 	return errors.Is(err, ErrAlreadyExists)
 }
 
+// IsBinaryNotFound returns true if the error is due to a missing nydus binary
+func IsBinaryNotFound(err error) bool {
+	return errors.Is(err, ErrBinaryNotFound)
+}
+
 // IsConnectionClosed returns true if error is due to connection closed
 // this is used when snapshotter closed by sig term
 func IsConnectionClosed(err error) bool {
@@ -119,6 +119,16 @@ func WithLogLevel(logLevel string) NewFSOpt {
 	}
 }
 
+// WithDaemonsLogLevelUpdate applies the log level to daemons reconnected after
+// a snapshotter restart if update is set, overriding the level recorded for
+// them. Otherwise they keep the level they were last set to.
+func WithDaemonsLogLevelUpdate(update bool) NewFSOpt {
+	return func(d *filesystem) error {
+		d.syncLogLevel = update
+		return nil
+	}
+}
+
 func WithLogDir(dir string) NewFSOpt {
 	return func(d *filesystem) error {
 		if err := os.MkdirAll(dir, 0755); err != nil {
@@ -8,7 +8,6 @@ package nydus
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"time"
@@ -24,13 +23,15 @@ import (
 	fspkg "github.com/containerd/nydus-snapshotter/pkg/filesystem/fs"
 	"github.com/containerd/nydus-snapshotter/pkg/filesystem/meta"
 	"github.com/containerd/nydus-snapshotter/pkg/label"
+	"github.com/containerd/nydus-snapshotter/pkg/nydussdk/model"
 	"github.com/containerd/nydus-snapshotter/pkg/process"
 	"github.com/containerd/nydus-snapshotter/pkg/signature"
+	"github.com/containerd/nydus-snapshotter/pkg/utils/redact"
 	"github.com/containerd/nydus-snapshotter/pkg/utils/retry"
 )
 
-// TODO: Move snapshotter needed all image annotations to nydus-snapshotter.
-const LayerAnnotationNydusBlobIDs = "containerd.io/snapshot/nydus-blob-ids"
+// Deprecated: use label.NydusBlobIDs instead.
+const LayerAnnotationNydusBlobIDs = label.NydusBlobIDs
 
 type filesystem struct {
 	meta.FileSystemMeta
@@ -43,6 +44,7 @@ type filesystem struct {
 	nydusdBinaryPath string
 	mode             fspkg.Mode
 	logLevel         string
+	syncLogLevel     bool
 	logDir           string
 	logToStdout      bool
 	nydusdThreadNum  int
@@ -62,9 +64,30 @@ func NewFileSystem(ctx context.Context, opt ...NewFSOpt) (fspkg.FileSystem, erro
 	if err := fs.manager.Reconnect(ctx); err != nil {
 		return nil, errors.Wrap(err, "failed to reconnect daemons")
 	}
+	if fs.syncLogLevel {
+		fs.applyDaemonsLogLevel(ctx)
+	}
 	return &fs, nil
 }
 
+// applyDaemonsLogLevel applies the current log level to reconnected daemons,
+// which otherwise keep the level recorded for them across snapshotter restarts.
+func (fs *filesystem) applyDaemonsLogLevel(ctx context.Context) {
+	for _, d := range fs.manager.ListDaemons() {
+		// Virtual daemons are served by the shared daemon process.
+		if (fs.mode == fspkg.SharedInstance || fs.mode == fspkg.PrefetchInstance) &&
+			d.ID != daemon.SharedNydusDaemonID {
+			continue
+		}
+		if d.LogLevel == fs.logLevel {
+			continue
+		}
+		if err := fs.manager.SetDaemonConfig(d, model.DaemonConfig{LogLevel: fs.logLevel}); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to update log level of daemon %s to %s", d.ID, fs.logLevel)
+		}
+	}
+}
+
 func (fs *filesystem) newSharedDaemon() (*daemon.Daemon, error) {
 	modeOpt := daemon.WithSharedDaemon()
 	if fs.mode == fspkg.PrefetchInstance {
@@ -111,7 +134,7 @@ func (fs *filesystem) Mount(ctx context.Context, snapshotID string, labels map[s
 
 	imageID, ok := labels[label.ImageRef]
 	if !ok {
-		return fmt.Errorf("failed to find image ref of snapshot %s, labels %v", snapshotID, labels)
+		return fmt.Errorf("failed to find image ref of snapshot %s, labels %v", snapshotID, redact.Labels(labels))
 	}
 	d, err := fs.newDaemon(ctx, snapshotID, imageID)
 	// if daemon already exists for snapshotID, just return
@@ -273,7 +296,7 @@ func (fs *filesystem) addSnapshot(imageID string, labels map[string]string) erro
 		return nil
 	}
 
-	blobs, err := fs.getBlobIDs(labels)
+	blobs, err := label.ParseBlobIDs(labels)
 	if err != nil {
 		return err
 	}
@@ -428,15 +451,3 @@ func (fs *filesystem) generateDaemonConfig(d *daemon.Daemon, labels map[string]s
 func (fs *filesystem) hasDaemon() bool {
 	return fs.mode != fspkg.NoneInstance && fs.mode != fspkg.PrefetchInstance
 }
-
-func (fs *filesystem) getBlobIDs(labels map[string]string) ([]string, error) {
-	idStr, ok := labels[LayerAnnotationNydusBlobIDs]
-	if !ok {
-		return nil, errors.New("no blob ids found")
-	}
-	var result []string
-	if err := json.Unmarshal([]byte(idStr), &result); err != nil {
-		return nil, err
-	}
-	return result, nil
-}
@@ -9,7 +9,6 @@ package stargz
 import (
 	"context"
 	"fmt"
-	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -27,6 +26,8 @@ import (
 	"github.com/containerd/nydus-snapshotter/pkg/filesystem/meta"
 	"github.com/containerd/nydus-snapshotter/pkg/label"
 	"github.com/containerd/nydus-snapshotter/pkg/process"
+	"github.com/containerd/nydus-snapshotter/pkg/utils/file"
+	"github.com/containerd/nydus-snapshotter/pkg/utils/redact"
 	"github.com/containerd/nydus-snapshotter/pkg/utils/retry"
 )
 
@@ -75,29 +76,31 @@ func (f *filesystem) PrepareLayer(ctx context.Context, s storage.Snapshot, label
 	}()
 	ref, layerDigest := parseLabels(labels)
 	if ref == "" || layerDigest == "" {
-		return fmt.Errorf("can not find ref and digest from label %+v", labels)
+		return fmt.Errorf("can not find ref and digest from label %+v", redact.Labels(labels))
 	}
 	keychain := auth.FromLabels(labels)
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "stargz prepare layer cancelled")
+	}
 	blob, err := f.resolver.GetBlob(ref, layerDigest, keychain)
 	if err != nil {
 		return errors.Wrapf(err, "failed to get blob from ref %s, digest %s", ref, layerDigest)
 	}
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "stargz prepare layer cancelled")
+	}
 	r, err := blob.ReadToc()
 	if err != nil {
 		return errors.Wrapf(err, "failed to read toc from ref %s, digest %s", ref, layerDigest)
 	}
-	starGzToc, err := os.OpenFile(filepath.Join(f.UpperPath(s.ID), stargzToc), os.O_CREATE|os.O_RDWR, 0755)
-	if err != nil {
-		return errors.Wrap(err, "failed to create stargz index")
-	}
-	_, err = io.Copy(starGzToc, r)
-	if err != nil {
+	if err := file.AtomicWrite(filepath.Join(f.UpperPath(s.ID), stargzToc), r, 0755); err != nil {
 		return errors.Wrap(err, "failed to save stargz index")
 	}
+	bootstrap := filepath.Join(f.UpperPath(s.ID), "image.boot")
 	options := []string{
 		"create",
 		"--source-type", "stargz_index",
-		"--bootstrap", filepath.Join(f.UpperPath(s.ID), "image.boot"),
+		"--bootstrap", bootstrap,
 		"--blob-id", digest(layerDigest).Sha256(),
 		"--repeatable",
 		"--disable-check",
@@ -111,11 +114,25 @@ func (f *filesystem) PrepareLayer(ctx context.Context, s storage.Snapshot, label
 			"--parent-bootstrap", parentBootstrap)
 	}
 	options = append(options, filepath.Join(f.UpperPath(s.ID), stargzToc))
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "stargz prepare layer cancelled")
+	}
 	log.G(ctx).Infof("nydus image command %v", options)
-	cmd := exec.Command(f.nydusdImageBinaryPath, options...)
+	// Kill the builder if the prepare request is cancelled, and don't leave a
+	// partial bootstrap behind for child layers to build on.
+	cmd := exec.CommandContext(ctx, f.nydusdImageBinaryPath, options...)
 	cmd.Stderr = os.Stderr
 	cmd.Stdout = os.Stdout
-	return cmd.Run()
+	if err := cmd.Run(); err != nil {
+		if rerr := os.Remove(bootstrap); rerr != nil && !os.IsNotExist(rerr) {
+			log.G(ctx).WithError(rerr).Warnf("failed to remove partial bootstrap %s", bootstrap)
+		}
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "nydus image command cancelled")
+		}
+		return err
+	}
+	return nil
 }
 
 func getParentSnapshotID(s storage.Snapshot) string {
@@ -165,7 +182,7 @@ func (f *filesystem) createNewDaemon(snapshotID string, imageID string) (*daemon
 func (f *filesystem) Mount(ctx context.Context, snapshotID string, labels map[string]string) error {
 	imageID, ok := labels[label.ImageRef]
 	if !ok {
-		return fmt.Errorf("failed to find image ref of snapshot %s, labels %v", snapshotID, labels)
+		return fmt.Errorf("failed to find image ref of snapshot %s, labels %v", snapshotID, redact.Labels(labels))
 	}
 	d, err := f.createNewDaemon(snapshotID, imageID)
 	// if daemon already exists for snapshotID, just return
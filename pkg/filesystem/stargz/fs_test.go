@@ -7,14 +7,26 @@
 package stargz
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
+	"github.com/containerd/containerd/snapshots/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -111,3 +123,103 @@ func Test_filesystem_generateDaemonConfig(t *testing.T) {
 	assert.Nil(t, ensureExists(filepath.Join(snapshotRoot, "config", d.ID, "config.json")))
 	assert.Nil(t, ensureExists(filepath.Join(snapshotRoot, "socket", d.ID)))
 }
+
+// stargzBlob builds a minimal stargz blob holding only the toc.
+func stargzBlob(t *testing.T) []byte {
+	var blob bytes.Buffer
+	zw := gzip.NewWriter(&blob)
+	tw := tar.NewWriter(zw)
+	toc := []byte(`{"version":1,"entries":[]}`)
+	require.Nil(t, tw.WriteHeader(&tar.Header{Name: stargzToc, Mode: 0644, Size: int64(len(toc))}))
+	_, err := tw.Write(toc)
+	require.Nil(t, err)
+	require.Nil(t, tw.Close())
+	require.Nil(t, zw.Close())
+
+	// The footer is an empty gzip member carrying the toc offset in its extra
+	// field, built by hand as compress/gzip can't produce the 47 bytes layout.
+	extra := fmt.Sprintf("%016xSTARGZ", 0)
+	blob.Write([]byte{0x1f, 0x8b, 0x08, 0x04, 0, 0, 0, 0, 0, 0xff, byte(len(extra)), 0})
+	blob.WriteString(extra)
+	blob.Write([]byte{0x01, 0x00, 0x00, 0xff, 0xff, 0, 0, 0, 0, 0, 0, 0, 0})
+	return blob.Bytes()
+}
+
+func Test_filesystem_PrepareLayerCancel(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "stargz-prepare-")
+	require.Nil(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	// The fake builder writes a partial bootstrap and its pid, then hangs
+	// until it's killed.
+	pidFile := filepath.Join(tmpDir, "builder.pid")
+	builder := filepath.Join(tmpDir, "nydus-image")
+	script := fmt.Sprintf(`#!/bin/sh
+while [ $# -gt 0 ]; do
+	if [ "$1" = "--bootstrap" ]; then
+		echo partial > "$2"
+	fi
+	shift
+done
+echo $$ > %s.tmp && mv %s.tmp %s
+exec sleep 30
+`, pidFile, pidFile, pidFile)
+	require.Nil(t, ioutil.WriteFile(builder, []byte(script), 0755))
+
+	blob := stargzBlob(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/blobs/") {
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(blob))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	f := filesystem{
+		FileSystemMeta: meta.FileSystemMeta{
+			RootDir: filepath.Join(tmpDir, "snapshot"),
+		},
+		resolver:              NewResolver(),
+		nydusdImageBinaryPath: builder,
+	}
+	s := storage.Snapshot{ID: "1"}
+	require.Nil(t, os.MkdirAll(f.UpperPath(s.ID), 0755))
+	labels := map[string]string{
+		label.ImageRef:          strings.TrimPrefix(srv.URL, "http://") + "/test/image:latest",
+		label.CRIDigest:         "sha256:" + strings.Repeat("a", 64),
+		label.ImagePullUsername: "mock",
+		label.ImagePullSecret:   "mock",
+	}
+
+	// A cancelled request returns before touching the registry.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = f.PrepareLayer(ctx, s, labels)
+	require.True(t, errors.Is(err, context.Canceled))
+	_, err = os.Stat(pidFile)
+	require.True(t, os.IsNotExist(err))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		for {
+			if _, err := os.Stat(pidFile); err == nil {
+				cancel()
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	err = f.PrepareLayer(ctx, s, labels)
+	require.True(t, errors.Is(err, context.Canceled), "unexpected error %v", err)
+
+	content, err := ioutil.ReadFile(pidFile)
+	require.Nil(t, err)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	require.Nil(t, err)
+	require.Equal(t, syscall.ESRCH, syscall.Kill(pid, 0), "builder is still running")
+
+	_, err = os.Stat(filepath.Join(f.UpperPath(s.ID), "image.boot"))
+	require.True(t, os.IsNotExist(err), "partial bootstrap is left behind")
+}
@@ -27,6 +27,8 @@ import (
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	"github.com/pkg/errors"
+
+	"github.com/containerd/nydus-snapshotter/pkg/utils/redact"
 )
 
 const (
@@ -166,7 +168,7 @@ func (r *Resolver) resolve(ref, digest string, keychain authn.Keychain) (*io.Sec
 
 	size, err := getSize(url, tr)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to get size from url %s", url)
+		return nil, errors.Wrapf(err, "failed to get size from url %s", redact.URL(url))
 	}
 	log.L.Infof("get size %d", size)
 
@@ -183,7 +185,7 @@ func (r *Resolver) resolve(ref, digest string, keychain authn.Keychain) (*io.Sec
 		req.Header.Set("Range", r)
 		res, err := tr.RoundTrip(req)
 		if err != nil {
-			return 0, err
+			return 0, redact.URLError(err)
 		}
 		defer func() {
 			io.Copy(ioutil.Discard, res.Body)
@@ -263,7 +265,7 @@ func redirect(endpointURL string, tr http.RoundTripper) (url string, err error)
 	req.Header.Set("Range", "bytes=0-0")
 	res, err := tr.RoundTrip(req)
 	if err != nil {
-		return "", errors.Wrapf(err, "failed to request to %q", endpointURL)
+		return "", errors.Wrapf(redact.URLError(err), "failed to request to %q", redact.URL(endpointURL))
 	}
 	defer func() {
 		io.Copy(ioutil.Discard, res.Body)
@@ -275,7 +277,7 @@ func redirect(endpointURL string, tr http.RoundTripper) (url string, err error)
 	} else if redir := res.Header.Get("Location"); redir != "" && res.StatusCode/100 == 3 {
 		url = redir
 	} else {
-		return "", fmt.Errorf("failed to access to %q with code %v", endpointURL, res.StatusCode)
+		return "", fmt.Errorf("failed to access to %q with code %v", redact.URL(endpointURL), res.StatusCode)
 	}
 	return
 }
@@ -291,7 +293,7 @@ func getSize(url string, tr http.RoundTripper) (int64, error) {
 	req.Header.Set("Range", "bytes=0-0")
 	res, err := tr.RoundTrip(req)
 	if err != nil {
-		return 0, err
+		return 0, redact.URLError(err)
 	}
 	defer func() {
 		io.Copy(ioutil.Discard, res.Body)
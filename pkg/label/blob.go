@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2022. Ant Group. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package label
+
+import (
+	"encoding/json"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// BlobIDsFromLayers returns the ordered, deduplicated list of nydus blob IDs
+// referenced by the layers of an image manifest. Nydus blob layers are marked
+// with the NydusDataLayer annotation, and a blob ID is the hex encoded part
+// of the layer digest. It fails if a blob layer has a malformed digest.
+func BlobIDsFromLayers(layers []ocispec.Descriptor) ([]string, error) {
+	var ids []string
+	seen := make(map[string]struct{})
+	for _, layer := range layers {
+		if _, ok := layer.Annotations[NydusDataLayer]; !ok {
+			continue
+		}
+		if err := layer.Digest.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "invalid digest %q of blob layer", layer.Digest)
+		}
+		id := layer.Digest.Hex()
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// MarshalBlobIDs encodes blob IDs as the value of the NydusBlobIDs annotation.
+func MarshalBlobIDs(ids []string) (string, error) {
+	if ids == nil {
+		ids = []string{}
+	}
+	b, err := json.Marshal(ids)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal blob ids")
+	}
+	return string(b), nil
+}
+
+// ParseBlobIDs decodes blob IDs from the NydusBlobIDs annotation in labels.
+func ParseBlobIDs(labels map[string]string) ([]string, error) {
+	idStr, ok := labels[NydusBlobIDs]
+	if !ok {
+		return nil, errors.New("no blob ids found")
+	}
+	var ids []string
+	if err := json.Unmarshal([]byte(idStr), &ids); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal blob ids %q", idStr)
+	}
+	return ids, nil
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright (c) 2022. Ant Group. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package label
+
+import (
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobIDs(t *testing.T) {
+	blob1 := digest.FromString("blob1")
+	blob2 := digest.FromString("blob2")
+	layers := []ocispec.Descriptor{
+		{Digest: blob1, Annotations: map[string]string{NydusDataLayer: "true"}},
+		{Digest: blob2, Annotations: map[string]string{NydusDataLayer: "true"}},
+		{Digest: blob1, Annotations: map[string]string{NydusDataLayer: "true"}},
+		{Digest: digest.FromString("bootstrap"), Annotations: map[string]string{NydusMetaLayer: "true"}},
+	}
+
+	ids, err := BlobIDsFromLayers(layers)
+	require.Nil(t, err)
+	require.Equal(t, []string{blob1.Hex(), blob2.Hex()}, ids)
+
+	_, err = BlobIDsFromLayers([]ocispec.Descriptor{
+		{Digest: "sha256:invalid", Annotations: map[string]string{NydusDataLayer: "true"}},
+	})
+	require.NotNil(t, err)
+
+	value, err := MarshalBlobIDs(ids)
+	require.Nil(t, err)
+	parsed, err := ParseBlobIDs(map[string]string{NydusBlobIDs: value})
+	require.Nil(t, err)
+	require.Equal(t, ids, parsed)
+
+	value, err = MarshalBlobIDs(nil)
+	require.Nil(t, err)
+	require.Equal(t, "[]", value)
+
+	_, err = ParseBlobIDs(map[string]string{})
+	require.NotNil(t, err)
+}
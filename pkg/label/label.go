@@ -19,4 +19,5 @@ const (
 	RemoteLabel         = "containerd.io/snapshot/remote"
 	NydusMetaLayer      = "containerd.io/snapshot/nydus-bootstrap"
 	NydusDataLayer      = "containerd.io/snapshot/nydus-blob"
+	NydusBlobIDs        = "containerd.io/snapshot/nydus-blob-ids"
 )
@@ -38,6 +38,7 @@ type Interface interface {
 	SharedMount(sharedMountPoint, bootstrap, daemonConfig string) error
 	Umount(sharedMountPoint string) error
 	GetFsMetric(sharedDaemon bool, sid string) (*model.FsMetric, error)
+	SetConfig(conf model.DaemonConfig) error
 }
 
 type NydusClient struct {
@@ -89,7 +90,7 @@ func (c *NydusClient) Umount(sharedMountPoint string) error {
 	if resp.StatusCode == http.StatusNoContent {
 		return nil
 	}
-	return handleMountError(resp.Body)
+	return handleError(resp.Body)
 }
 
 func (c *NydusClient) GetFsMetric(sharedDaemon bool, sid string) (*model.FsMetric, error) {
@@ -121,6 +122,29 @@ func (c *NydusClient) GetFsMetric(sharedDaemon bool, sid string) (*model.FsMetri
 	return &m, nil
 }
 
+// SetConfig updates the configuration of a running nydusd in place.
+func (c *NydusClient) SetConfig(conf model.DaemonConfig) error {
+	body, err := json.Marshal(conf)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal daemon config")
+	}
+	requestURL := fmt.Sprintf("http://unix%s", infoEndpoint)
+	req, err := http.NewRequest(http.MethodPut, requestURL, bytes.NewBuffer(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to create set config request")
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to do HTTP PUT to %s", requestURL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return handleError(resp.Body)
+}
+
 func (c *NydusClient) SharedMount(sharedMountPoint, bootstrap, daemonConfig string) error {
 	requestURL := fmt.Sprintf("http://unix%s?mountpoint=%s", mountEndpoint, sharedMountPoint)
 	content, err := ioutil.ReadFile(daemonConfig)
@@ -139,7 +163,7 @@ func (c *NydusClient) SharedMount(sharedMountPoint, bootstrap, daemonConfig stri
 	if resp.StatusCode == http.StatusNoContent {
 		return nil
 	}
-	return handleMountError(resp.Body)
+	return handleError(resp.Body)
 }
 
 func waitUntilSocketReady(sock string) error {
@@ -173,7 +197,9 @@ func buildTransport(sock string) (http.RoundTripper, error) {
 		},
 	}, nil
 }
-func handleMountError(r io.Reader) error {
+
+// handleError decodes the error message nydusd returns on a failed API request.
+func handleError(r io.Reader) error {
 	b, err := ioutil.ReadAll(r)
 	if err != nil {
 		return errors.Wrap(err, "failed to read from reader")
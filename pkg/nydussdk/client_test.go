@@ -65,3 +65,31 @@ func TestNydusClient_CheckStatus(t *testing.T) {
 	assert.Equal(t, "testid", info.ID)
 	assert.Equal(t, BTI, info.Version)
 }
+
+func TestNydusClient_SetConfig(t *testing.T) {
+	mockSocket := "testdata/nydus-config.sock"
+	_ = os.Remove(mockSocket)
+
+	var received model.DaemonConfig
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != infoEndpoint {
+			w.WriteHeader(http.StatusBadRequest)
+			j, _ := json.Marshal(model.ErrorMessage{Code: "Unsupported", Message: "unexpected request"})
+			w.Write(j)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	unixListener, err := net.Listen("unix", mockSocket)
+	require.Nil(t, err)
+	ts.Listener = unixListener
+	ts.Start()
+	defer ts.Close()
+
+	client, err := NewNydusClient(mockSocket)
+	require.Nil(t, err)
+	err = client.SetConfig(model.DaemonConfig{LogLevel: "debug"})
+	require.Nil(t, err)
+	assert.Equal(t, "debug", received.LogLevel)
+}
@@ -20,6 +20,12 @@ type DaemonInfo struct {
 	State   string        `json:"state"`
 }
 
+// DaemonConfig holds the nydusd options which can be changed at runtime
+// without restarting the daemon.
+type DaemonConfig struct {
+	LogLevel string `json:"log_level,omitempty"`
+}
+
 type ErrorMessage struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
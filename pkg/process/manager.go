@@ -21,6 +21,7 @@ import (
 	"github.com/containerd/nydus-snapshotter/config"
 	"github.com/containerd/nydus-snapshotter/pkg/daemon"
 	"github.com/containerd/nydus-snapshotter/pkg/errdefs"
+	"github.com/containerd/nydus-snapshotter/pkg/nydussdk/model"
 	"github.com/containerd/nydus-snapshotter/pkg/store"
 	"github.com/containerd/nydus-snapshotter/pkg/utils/mount"
 )
@@ -105,6 +106,25 @@ func (m *Manager) CleanUpDaemonResource(d *daemon.Daemon) {
 	}
 }
 
+// SetDaemonConfig updates the configuration of a running daemon without
+// restarting it, and persists the new values in the database so they survive
+// snapshotter and daemon restarts.
+func (m *Manager) SetDaemonConfig(d *daemon.Daemon, conf model.DaemonConfig) error {
+	if err := d.SetConfig(conf); err != nil {
+		return errors.Wrapf(err, "failed to set config of daemon %s", d.ID)
+	}
+
+	updated := *d
+	if conf.LogLevel != "" {
+		updated.LogLevel = conf.LogLevel
+	}
+	if err := m.store.Update(&updated); err != nil {
+		return errors.Wrapf(err, "failed to update daemon %s info to db", d.ID)
+	}
+	d.LogLevel = updated.LogLevel
+	return nil
+}
+
 func (m *Manager) StartDaemon(d *daemon.Daemon) error {
 	// if cg != nil {
 	// 	err := cg(d)
@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2022. Ant Group. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package process
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/containerd/nydus-snapshotter/pkg/daemon"
+	"github.com/containerd/nydus-snapshotter/pkg/nydussdk"
+	"github.com/containerd/nydus-snapshotter/pkg/nydussdk/model"
+	"github.com/containerd/nydus-snapshotter/pkg/store"
+)
+
+type fakeClient struct {
+	nydussdk.Interface
+	conf model.DaemonConfig
+	err  error
+}
+
+func (c *fakeClient) SetConfig(conf model.DaemonConfig) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.conf = conf
+	return nil
+}
+
+func persistedLogLevel(t *testing.T, db *store.Database, id string) string {
+	var level string
+	err := db.WalkDaemons(context.TODO(), func(d *daemon.Daemon) error {
+		if d.ID == id {
+			level = d.LogLevel
+		}
+		return nil
+	})
+	require.Nil(t, err)
+	return level
+}
+
+func TestSetDaemonConfig(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "nydus-manager-")
+	require.Nil(t, err)
+	defer os.RemoveAll(rootDir)
+
+	db, err := store.NewDatabase(rootDir)
+	require.Nil(t, err)
+	defer db.Close()
+
+	mgr, err := NewManager(Opt{Database: db})
+	require.Nil(t, err)
+
+	client := &fakeClient{}
+	d := &daemon.Daemon{ID: "d1", SnapshotID: "s1", LogLevel: "info", Client: client, Once: &sync.Once{}}
+	require.Nil(t, mgr.NewDaemon(d))
+
+	require.Nil(t, mgr.SetDaemonConfig(d, model.DaemonConfig{LogLevel: "debug"}))
+	require.Equal(t, "debug", client.conf.LogLevel)
+	require.Equal(t, "debug", d.LogLevel)
+	require.Equal(t, "debug", persistedLogLevel(t, db, d.ID))
+
+	// Invalid levels are rejected before reaching nydusd.
+	require.NotNil(t, mgr.SetDaemonConfig(d, model.DaemonConfig{LogLevel: "verbose"}))
+	require.Equal(t, "debug", client.conf.LogLevel)
+	require.Equal(t, "debug", d.LogLevel)
+
+	// Nothing is recorded if nydusd fails to apply the change.
+	client.err = errors.New("nydusd failure")
+	require.NotNil(t, mgr.SetDaemonConfig(d, model.DaemonConfig{LogLevel: "warn"}))
+	require.Equal(t, "debug", d.LogLevel)
+	require.Equal(t, "debug", persistedLogLevel(t, db, d.ID))
+
+	// Nor if persisting fails, the daemon is unknown to the store.
+	client.err = nil
+	unknown := &daemon.Daemon{ID: "d2", SnapshotID: "s2", LogLevel: "info", Client: client, Once: &sync.Once{}}
+	require.NotNil(t, mgr.SetDaemonConfig(unknown, model.DaemonConfig{LogLevel: "warn"}))
+	require.Equal(t, "info", unknown.LogLevel)
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2022. Ant Group. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package file
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// AtomicWrite writes content from r to path durably. The content is written
+// into a temporary file in the same directory, fsynced and then renamed to
+// path, so that a crash never leaves a truncated or zero-length file behind
+// which later mounts would trust.
+func AtomicWrite(path string, r io.Reader, perm os.FileMode) (retErr error) {
+	dir := filepath.Dir(path)
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".tmp-")
+	if err != nil {
+		return errors.Wrapf(err, "failed to create temp file in %s", dir)
+	}
+	defer func() {
+		if retErr != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		return errors.Wrapf(err, "failed to write temp file %s", tmp.Name())
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return errors.Wrapf(err, "failed to chmod temp file %s", tmp.Name())
+	}
+	if err := tmp.Sync(); err != nil {
+		return errors.Wrapf(err, "failed to sync temp file %s", tmp.Name())
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "failed to close temp file %s", tmp.Name())
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return errors.Wrapf(err, "failed to rename %s to %s", tmp.Name(), path)
+	}
+
+	return syncDir(dir)
+}
+
+// AtomicWriteFile is like AtomicWrite but takes the content as a byte slice.
+func AtomicWriteFile(path string, data []byte, perm os.FileMode) error {
+	return AtomicWrite(path, bytes.NewReader(data), perm)
+}
+
+// syncDir persists the directory entry of a renamed file.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open dir %s", dir)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return errors.Wrapf(err, "failed to sync dir %s", dir)
+	}
+	return nil
+}
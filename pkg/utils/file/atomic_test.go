@@ -0,0 +1,39 @@
+/*
+ * Copyright (c) 2022. Ant Group. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAtomicWriteFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atomic-write-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	require.Nil(t, ioutil.WriteFile(path, []byte("old"), 0644))
+	require.Nil(t, AtomicWriteFile(path, []byte("new"), 0600))
+
+	content, err := ioutil.ReadFile(path)
+	require.Nil(t, err)
+	require.Equal(t, "new", string(content))
+
+	info, err := os.Stat(path)
+	require.Nil(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// No temporary files should be left behind.
+	entries, err := ioutil.ReadDir(dir)
+	require.Nil(t, err)
+	require.Len(t, entries, 1)
+}
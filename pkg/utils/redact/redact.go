@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2022. Ant Group. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package redact masks credentials before snapshot labels, signed URLs and
+// nydusd configurations reach logs or error messages.
+package redact
+
+import (
+	"errors"
+	"net/url"
+	"sync/atomic"
+
+	"github.com/containerd/nydus-snapshotter/pkg/label"
+)
+
+const mask = "******"
+
+var (
+	// Redaction is enabled by default, it's only meant to be disabled
+	// temporarily when debugging credential issues.
+	disabled int32
+
+	secretLabels = []string{
+		label.ImagePullSecret,
+	}
+)
+
+// SetEnabled switches redaction on or off at runtime.
+func SetEnabled(enabled bool) {
+	var v int32
+	if !enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&disabled, v)
+}
+
+// Enabled reports whether redaction is on.
+func Enabled() bool {
+	return atomic.LoadInt32(&disabled) == 0
+}
+
+// Secret masks a non-empty secret value.
+func Secret(s string) string {
+	if !Enabled() || s == "" {
+		return s
+	}
+	return mask
+}
+
+// Labels returns a copy of snapshot labels with secret values masked.
+func Labels(labels map[string]string) map[string]string {
+	if !Enabled() {
+		return labels
+	}
+	res := make(map[string]string, len(labels))
+	for k, v := range labels {
+		res[k] = v
+	}
+	for _, k := range secretLabels {
+		res[k] = Secret(res[k])
+	}
+	return res
+}
+
+// URL strips the user info and query of a URL, which carry credentials or
+// signatures in presigned blob URLs returned by registry redirects.
+func URL(rawURL string) string {
+	if !Enabled() {
+		return rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return mask
+	}
+	if u.User != nil {
+		u.User = url.User(mask)
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = mask
+	}
+	u.Fragment = ""
+	return u.String()
+}
+
+// URLError redacts the URL embedded in an *url.Error returned by HTTP
+// clients and transports, which is printed as part of the error message.
+func URLError(err error) error {
+	var uerr *url.Error
+	if !Enabled() || !errors.As(err, &uerr) {
+		return err
+	}
+	uerr.URL = URL(uerr.URL)
+	return err
+}
@@ -12,6 +12,7 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -37,6 +38,7 @@ import (
 	"github.com/containerd/nydus-snapshotter/pkg/process"
 	"github.com/containerd/nydus-snapshotter/pkg/signature"
 	"github.com/containerd/nydus-snapshotter/pkg/snapshot"
+	"github.com/containerd/nydus-snapshotter/pkg/utils/redact"
 )
 
 const (
@@ -82,6 +84,11 @@ func NewSnapshotter(ctx context.Context, cfg *config.Config) (snapshots.Snapshot
 		return nil, errors.Wrap(err, "failed to initialize verifier")
 	}
 
+	redact.SetEnabled(!cfg.DisableLogRedaction)
+	if cfg.DisableLogRedaction {
+		log.G(ctx).Warn("Log redaction is disabled, credentials may be printed in logs")
+	}
+
 	cfg.DaemonMode = strings.ToLower(cfg.DaemonMode)
 	if cfg.DaemonMode == config.DaemonModePrefetch && !cfg.DaemonCfg.FSPrefetch.Enable {
 		log.G(ctx).Warnf("Daemon mode is %s but fs_prefetch is not enabled, change to %s mode", cfg.DaemonMode, config.DaemonModeNone)
@@ -111,6 +118,7 @@ func NewSnapshotter(ctx context.Context, cfg *config.Config) (snapshots.Snapshot
 		nydus.WithVerifier(verifier),
 		nydus.WithDaemonMode(cfg.DaemonMode),
 		nydus.WithLogLevel(cfg.LogLevel),
+		nydus.WithDaemonsLogLevelUpdate(cfg.LogLevelSet),
 		nydus.WithLogDir(cfg.LogDir),
 		nydus.WithLogToStdout(cfg.LogToStdout),
 		nydus.WithNydusdThreadNum(cfg.NydusdThreadNum),
@@ -292,7 +300,7 @@ func (o *snapshotter) Prepare(ctx context.Context, key, parent string, opts ...s
 		}
 	}
 
-	logCtx.Infof("Preparing. Key=%s, Parent=%s, Labels %v", key, parent, base.Labels)
+	logCtx.Infof("Preparing. Key=%s, Parent=%s, Labels %v", key, parent, redact.Labels(base.Labels))
 	if target, ok := base.Labels[label.TargetSnapshotLabel]; ok {
 		// check if image layer is nydus layer
 		if o.fs.Support(ctx, base.Labels) {
@@ -308,6 +316,10 @@ func (o *snapshotter) Prepare(ctx context.Context, key, parent string, opts ...s
 			// Mark this snapshot as remote
 			base.Labels[label.RemoteLabel] = "remote snapshot"
 			err := o.stargzFs.PrepareLayer(ctx, s, base.Labels)
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				// Don't fall back to unpacking the layer with a dead context.
+				return nil, err
+			}
 			if err != nil {
 				logCtx.Errorf("failed to prepare stargz layer of snapshot ID %s, err: %v", s.ID, err)
 			} else {
@@ -617,7 +629,7 @@ func (o *snapshotter) remoteMounts(ctx context.Context, s storage.Snapshot, id s
 
 	cfg, err := o.fs.NewDaemonConfig(labels)
 	if err != nil {
-		return nil, errors.Wrapf(err, fmt.Sprintf("remoteMounts: failed to generate nydus config for snapshot %s, label: %v", id, labels))
+		return nil, errors.Wrapf(err, fmt.Sprintf("remoteMounts: failed to generate nydus config for snapshot %s, label: %v", id, redact.Labels(labels)))
 	}
 
 	b, err := json.Marshal(cfg)
@@ -625,11 +637,9 @@ func (o *snapshotter) remoteMounts(ctx context.Context, s storage.Snapshot, id s
 		return nil, errors.Wrapf(err, "remoteMounts: failed to marshal config")
 	}
 	configContent := string(b)
-	// We already Marshal config and save it in configContent, reset Auth and
-	// RegistryToken so it could be printed and to make debug easier
-	cfg.Device.Backend.Config.Auth = ""
-	cfg.Device.Backend.Config.RegistryToken = ""
-	b, err = json.Marshal(cfg)
+	// We already Marshal config and save it in configContent, mask credentials
+	// so it could be printed and to make debug easier
+	b, err = json.Marshal(cfg.Redacted())
 	if err != nil {
 		return nil, errors.Wrapf(err, "remoteMounts: failed to marshal config")
 	}
@@ -642,19 +652,10 @@ func (o *snapshotter) remoteMounts(ctx context.Context, s storage.Snapshot, id s
 	}
 	defer f.Close()
 
-	header := make([]byte, 8)
-	_, err = f.Read(header)
+	version, err := detectFsVersion(f)
 	if err != nil {
 		return nil, errors.Wrapf(err, "remoteMounts: check bootstrap version: failed to read bootstrap")
 	}
-	magic := binary.LittleEndian.Uint32(header[0:4])
-	fsVersion := binary.LittleEndian.Uint32(header[4:8])
-	var version string
-	if magic == RafsV5SuperMagic && fsVersion == RafsSuperVersionV5 {
-		version = NydusRootfsV5
-	} else {
-		version = NydusRootfsV6
-	}
 	// when enable nydus-overlayfs, return unified mount slice for runc and kata
 	extraOption := &ExtraOption{
 		Source:      source,
@@ -680,6 +681,22 @@ func (o *snapshotter) remoteMounts(ctx context.Context, s storage.Snapshot, id s
 	}, nil
 }
 
+// detectFsVersion reads the superblock header of a bootstrap and returns its
+// nydus rootfs version. The header is decoded explicitly as little-endian, as
+// laid out by the builder, so the result doesn't depend on host byte order.
+func detectFsVersion(r io.Reader) (string, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return "", err
+	}
+	magic := binary.LittleEndian.Uint32(header[0:4])
+	fsVersion := binary.LittleEndian.Uint32(header[4:8])
+	if magic == RafsV5SuperMagic && fsVersion == RafsSuperVersionV5 {
+		return NydusRootfsV5, nil
+	}
+	return NydusRootfsV6, nil
+}
+
 func (o *snapshotter) mounts(ctx context.Context, s storage.Snapshot) ([]mount.Mount, error) {
 	if len(s.ParentIDs) == 0 {
 		// if we only have one layer/no parents then just return a bind mount as overlay
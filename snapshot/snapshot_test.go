@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2022. Ant Group. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/containerd/snapshots"
+	"github.com/containerd/containerd/snapshots/storage"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	fspkg "github.com/containerd/nydus-snapshotter/pkg/filesystem/fs"
+	"github.com/containerd/nydus-snapshotter/pkg/label"
+)
+
+func TestDetectFsVersion(t *testing.T) {
+	// RAFS v5 superblock header: magic 0x52414653 and version 0x500,
+	// both stored little-endian.
+	v5 := []byte{0x53, 0x46, 0x41, 0x52, 0x00, 0x05, 0x00, 0x00}
+	version, err := detectFsVersion(bytes.NewReader(v5))
+	require.Nil(t, err)
+	require.Equal(t, NydusRootfsV5, version)
+
+	// A big-endian encoded header must not be recognized as v5.
+	v5BigEndian := []byte{0x52, 0x41, 0x46, 0x53, 0x00, 0x00, 0x05, 0x00}
+	version, err = detectFsVersion(bytes.NewReader(v5BigEndian))
+	require.Nil(t, err)
+	require.Equal(t, NydusRootfsV6, version)
+
+	// RAFS v6 bootstraps start with an EROFS compatible layout.
+	version, err = detectFsVersion(bytes.NewReader(make([]byte, 1024)))
+	require.Nil(t, err)
+	require.Equal(t, NydusRootfsV6, version)
+
+	_, err = detectFsVersion(bytes.NewReader(v5[:4]))
+	require.NotNil(t, err)
+}
+
+type fakeFs struct {
+	fspkg.FileSystem
+	support bool
+	err     error
+}
+
+func (f *fakeFs) Support(ctx context.Context, labels map[string]string) bool {
+	return f.support
+}
+
+func (f *fakeFs) PrepareLayer(ctx context.Context, s storage.Snapshot, labels map[string]string) error {
+	return f.err
+}
+
+func (f *fakeFs) MountPoint(snapshotID string) (string, error) {
+	return "", errors.New("not a remote snapshot")
+}
+
+func TestPrepareStargzLayerCancelled(t *testing.T) {
+	root, err := ioutil.TempDir("", "nydus-snapshotter-")
+	require.Nil(t, err)
+	defer os.RemoveAll(root)
+	require.Nil(t, os.Mkdir(filepath.Join(root, "snapshots"), 0700))
+
+	ms, err := storage.NewMetaStore(filepath.Join(root, "metadata.db"))
+	require.Nil(t, err)
+	defer ms.Close()
+
+	stargzFs := &fakeFs{support: true}
+	o := &snapshotter{
+		root:     root,
+		ms:       ms,
+		fs:       &fakeFs{},
+		stargzFs: stargzFs,
+	}
+	labels := map[string]string{
+		label.TargetSnapshotLabel: "target",
+		label.CRIImageLayer:       "true",
+	}
+
+	// A cancelled stargz prepare is returned rather than unpacked with a
+	// dead context.
+	stargzFs.err = errors.Wrap(context.Canceled, "nydus image command cancelled")
+	_, err = o.Prepare(context.Background(), "cancelled", "", snapshots.WithLabels(labels))
+	require.True(t, errors.Is(err, context.Canceled))
+
+	stargzFs.err = errors.Wrap(context.DeadlineExceeded, "nydus image command cancelled")
+	_, err = o.Prepare(context.Background(), "timeout", "", snapshots.WithLabels(labels))
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// Other failures fall back to the normal OCI layer prepare.
+	stargzFs.err = errors.New("failed to read toc")
+	mounts, err := o.Prepare(context.Background(), "fallback", "", snapshots.WithLabels(labels))
+	require.Nil(t, err)
+	require.NotEmpty(t, mounts)
+}